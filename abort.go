@@ -0,0 +1,94 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrAborted is the reason reported by an AbortSignal aborted without one.
+var ErrAborted = errors.New("promise: aborted")
+
+// AbortController triggers its AbortSignal. Unlike a context.CancelFunc it
+// can be created and fired from code that has no context at hand, such as
+// GUI or OS signal handlers, and its signal can be shared by many tasks.
+type AbortController struct {
+	signal *AbortSignal
+}
+
+// NewAbortController returns a controller with a fresh, non-aborted signal.
+func NewAbortController() *AbortController {
+	return &AbortController{
+		signal: &AbortSignal{done: make(chan struct{})},
+	}
+}
+
+// Signal returns the signal controlled by c.
+func (c *AbortController) Signal() *AbortSignal {
+	return c.signal
+}
+
+// Abort aborts the signal with reason, or ErrAborted when reason is nil. Only
+// the first call has any effect.
+func (c *AbortController) Abort(reason error) {
+	if reason == nil {
+		reason = ErrAborted
+	}
+	s := c.signal
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.reason = reason
+		s.mu.Unlock()
+		close(s.done)
+	})
+}
+
+// AbortSignal is the read side of an AbortController. It is safe for
+// concurrent use.
+type AbortSignal struct {
+	once   sync.Once
+	mu     sync.Mutex
+	reason error
+	done   chan struct{}
+}
+
+// Done returns a channel that is closed when the signal is aborted.
+func (s *AbortSignal) Done() <-chan struct{} {
+	return s.done
+}
+
+// Aborted reports whether the signal has been aborted.
+func (s *AbortSignal) Aborted() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reason returns the abort reason, or nil if the signal was not aborted yet.
+func (s *AbortSignal) Reason() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reason
+}
+
+// Context returns a copy of parent that is canceled when the signal is
+// aborted, with the abort reason as its cause (see context.Cause). Callers
+// must call the returned CancelFunc once done with the context.
+func (s *AbortSignal) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	if s.Aborted() {
+		cancel(s.Reason())
+		return ctx, func() { cancel(context.Canceled) }
+	}
+	go func() {
+		select {
+		case <-s.done:
+			cancel(s.Reason())
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
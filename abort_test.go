@@ -0,0 +1,112 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAbortController(t *testing.T) {
+	t.Run("starts not aborted", func(t *testing.T) {
+		s := NewAbortController().Signal()
+
+		if s.Aborted() {
+			t.Fatal("Aborted() = true, want false")
+		}
+		if err := s.Reason(); err != nil {
+			t.Fatalf("Reason() = %v, want nil", err)
+		}
+		select {
+		case <-s.Done():
+			t.Fatal("Done() is closed before Abort")
+		default:
+		}
+	})
+
+	t.Run("first Abort wins", func(t *testing.T) {
+		c := NewAbortController()
+		first, second := errors.New("first"), errors.New("second")
+
+		c.Abort(first)
+		c.Abort(second)
+
+		s := c.Signal()
+		if !s.Aborted() {
+			t.Fatal("Aborted() = false, want true")
+		}
+		if err := s.Reason(); err != first {
+			t.Fatalf("Reason() = %v, want %v", err, first)
+		}
+		<-s.Done()
+	})
+
+	t.Run("defaults the reason to ErrAborted", func(t *testing.T) {
+		c := NewAbortController()
+		c.Abort(nil)
+
+		if err := c.Signal().Reason(); err != ErrAborted {
+			t.Fatalf("Reason() = %v, want %v", err, ErrAborted)
+		}
+	})
+}
+
+func TestAbortSignal_Context(t *testing.T) {
+	t.Run("is canceled with the reason as cause when aborted later", func(t *testing.T) {
+		c := NewAbortController()
+		reason := errors.New("user aborted")
+		ctx, cancel := c.Signal().Context(context.Background())
+		defer cancel()
+
+		c.Abort(reason)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("context was not canceled")
+		}
+		if err := context.Cause(ctx); err != reason {
+			t.Fatalf("context.Cause() = %v, want %v", err, reason)
+		}
+	})
+
+	t.Run("is already canceled when the signal was aborted", func(t *testing.T) {
+		c := NewAbortController()
+		c.Abort(nil)
+
+		ctx, cancel := c.Signal().Context(context.Background())
+		defer cancel()
+
+		if err := ctx.Err(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("ctx.Err() = %v, want %v", err, context.Canceled)
+		}
+		if err := context.Cause(ctx); err != ErrAborted {
+			t.Fatalf("context.Cause() = %v, want %v", err, ErrAborted)
+		}
+	})
+
+	t.Run("follows the parent context", func(t *testing.T) {
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancel := NewAbortController().Signal().Context(parent)
+		defer cancel()
+
+		cancelParent()
+
+		<-ctx.Done()
+		if err := context.Cause(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("context.Cause() = %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("is not affected by Abort after cancel", func(t *testing.T) {
+		c := NewAbortController()
+		ctx, cancel := c.Signal().Context(context.Background())
+
+		cancel()
+		c.Abort(errors.New("late"))
+
+		if err := context.Cause(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("context.Cause() = %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package promise
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ItemError is an error produced by one input of a combinator. Index is the
+// position of the input and Key its map key, if the input came from a map.
+type ItemError struct {
+	Index int
+	Key   any
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	if e.Key != nil {
+		return fmt.Sprintf("item %d (key %v): %v", e.Index, e.Key, e.Err)
+	}
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// AggregateError reports the failures of several inputs at once. Both
+// errors.Is and errors.As look through every member.
+type AggregateError struct {
+	Errors []*ItemError
+}
+
+func (e *AggregateError) Error() string {
+	var sb strings.Builder
+	if len(e.Errors) == 1 {
+		sb.WriteString("promise: 1 error:")
+	} else {
+		fmt.Fprintf(&sb, "promise: %d errors:", len(e.Errors))
+	}
+	for _, item := range e.Errors {
+		sb.WriteString("\n\t")
+		sb.WriteString(item.Error())
+	}
+	return sb.String()
+}
+
+func (e *AggregateError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, item := range e.Errors {
+		errs[i] = item
+	}
+	return errs
+}
+
+// ItemErrors returns every ItemError found in err's tree, in order, so that
+// callers can retry only the inputs that failed. Item errors nested inside
+// another ItemError are not reported separately.
+func ItemErrors(err error) []*ItemError {
+	var items []*ItemError
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *ItemError:
+			items = append(items, e)
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	return items
+}
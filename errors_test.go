@@ -0,0 +1,139 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+)
+
+type codeError struct{ code int }
+
+func (e *codeError) Error() string { return "code error" }
+
+func TestItemError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *ItemError
+		want string
+	}{
+		{"without key", &ItemError{Index: 3, Err: io.EOF}, "item 3: EOF"},
+		{"with key", &ItemError{Index: 1, Key: "user-42", Err: io.EOF}, "item 1 (key user-42): EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Fatalf("Error() = %q, want %q", got, tt.want)
+			}
+			if !errors.Is(tt.err, io.EOF) {
+				t.Fatal("errors.Is(err, io.EOF) = false, want true")
+			}
+		})
+	}
+}
+
+func TestAggregateError(t *testing.T) {
+	t.Run("renders a single error", func(t *testing.T) {
+		err := &AggregateError{Errors: []*ItemError{
+			{Index: 0, Err: io.EOF},
+		}}
+
+		want := "promise: 1 error:\n\titem 0: EOF"
+		if got := err.Error(); got != want {
+			t.Fatalf("Error() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("renders one line per member", func(t *testing.T) {
+		err := &AggregateError{Errors: []*ItemError{
+			{Index: 0, Err: io.EOF},
+			{Index: 2, Key: "b", Err: errors.New("boom")},
+		}}
+
+		want := "promise: 2 errors:\n\titem 0: EOF\n\titem 2 (key b): boom"
+		if got := err.Error(); got != want {
+			t.Fatalf("Error() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("matches members with errors.Is and errors.As", func(t *testing.T) {
+		code := &codeError{code: 7}
+		var err error = &AggregateError{Errors: []*ItemError{
+			{Index: 0, Err: io.EOF},
+			{Index: 1, Key: "k", Err: code},
+		}}
+
+		if !errors.Is(err, io.EOF) {
+			t.Fatal("errors.Is(err, io.EOF) = false, want true")
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatal("errors.Is(err, io.ErrUnexpectedEOF) = true, want false")
+		}
+
+		var ce *codeError
+		if !errors.As(err, &ce) || ce != code {
+			t.Fatalf("errors.As(err, *codeError) = %v, want %v", ce, code)
+		}
+
+		var ie *ItemError
+		if !errors.As(err, &ie) || ie.Index != 0 {
+			t.Fatalf("errors.As(err, *ItemError) = %v, want the first member", ie)
+		}
+	})
+}
+
+func TestItemErrors(t *testing.T) {
+	indexes := func(items []*ItemError) []int {
+		out := make([]int, len(items))
+		for i, item := range items {
+			out[i] = item.Index
+		}
+		return out
+	}
+
+	inner := &AggregateError{Errors: []*ItemError{
+		{Index: 1, Err: io.EOF},
+		{Index: 2, Err: io.EOF},
+	}}
+	tests := []struct {
+		name string
+		err  error
+		want []int
+	}{
+		{"nil", nil, []int{}},
+		{"unrelated error", io.EOF, []int{}},
+		{"single item", &ItemError{Index: 4, Err: io.EOF}, []int{4}},
+		{
+			"aggregate",
+			&AggregateError{Errors: []*ItemError{{Index: 0, Err: io.EOF}, {Index: 3, Err: io.EOF}}},
+			[]int{0, 3},
+		},
+		{
+			"nested aggregate",
+			&AggregateError{Errors: []*ItemError{
+				{Index: 0, Err: io.EOF},
+				{Index: 5, Err: inner},
+			}},
+			[]int{0, 5},
+		},
+		{"fmt.Errorf wrapped", fmt.Errorf("loading: %w", inner), []int{1, 2}},
+		{
+			"errors.Join",
+			errors.Join(&ItemError{Index: 9, Err: io.EOF}, io.ErrUnexpectedEOF, fmt.Errorf("wrapped: %w", inner)),
+			[]int{9, 1, 2},
+		},
+		{
+			"item inside an item",
+			&ItemError{Index: 7, Err: fmt.Errorf("retry: %w", &ItemError{Index: 8, Err: io.EOF})},
+			[]int{7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indexes(ItemErrors(tt.err)); !slices.Equal(got, tt.want) {
+				t.Fatalf("ItemErrors() indexes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
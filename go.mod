@@ -0,0 +1,3 @@
+module github.com/jamillosantos/promise
+
+go 1.21
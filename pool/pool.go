@@ -0,0 +1,249 @@
+// Package pool implements a fixed-size worker pool with a bounded queue,
+// graceful shutdown and panic isolation.
+//
+// A panicking task does not take down its worker or the process: the panic
+// is recovered and, unless WithPanicHandler says otherwise, logged with its
+// stack trace through the standard log package.
+package pool
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	// ErrClosed is returned when submitting to a pool that is shutting down.
+	ErrClosed = errors.New("pool: closed")
+	// ErrQueueFull is returned when the pool queue has no room for a task.
+	ErrQueueFull = errors.New("pool: queue full")
+)
+
+// Option configures a Pool.
+type Option func(*Pool)
+
+// WithQueueSize sets how many submitted tasks may wait for a free worker.
+// By default the queue holds as many tasks as the pool has workers.
+func WithQueueSize(n int) Option {
+	return func(p *Pool) {
+		p.queueSize = n
+	}
+}
+
+// WithBlockingSubmit makes submissions wait for room in the queue instead of
+// failing with ErrQueueFull, applying backpressure to producers. Blocked
+// submissions return ErrClosed if the pool is shut down; use SubmitContext to
+// bound the wait.
+func WithBlockingSubmit() Option {
+	return func(p *Pool) {
+		p.blocking = true
+	}
+}
+
+// WithPanicHandler sets a function that receives the value and stack trace
+// of any panic raised by a task, replacing the default of logging them.
+// Passing nil discards panics.
+func WithPanicHandler(fn func(v any, stack []byte)) Option {
+	return func(p *Pool) {
+		p.panicHandler = fn
+	}
+}
+
+// Priority orders queued tasks: workers always pick the oldest task of the
+// highest priority available. Dispatch is strictly by priority with no
+// aging, so a steady stream of higher-priority tasks starves lower ones
+// until the higher queues run empty.
+type Priority int
+
+const (
+	Low Priority = iota
+	Normal
+	High
+)
+
+// Pool runs submitted tasks on a fixed number of worker goroutines.
+type Pool struct {
+	queueSize    int
+	blocking     bool
+	panicHandler func(v any, stack []byte)
+
+	mu     sync.Mutex
+	cond   *sync.Cond // signaled when a task is queued
+	space  *sync.Cond // signaled when the queue has room for one more task
+	closed bool
+	queues [High + 1][]func()
+	queued int
+	idle   int
+	wg     sync.WaitGroup
+}
+
+// New starts a pool with size workers. A size lower than 1 uses
+// runtime.GOMAXPROCS(0).
+func New(size int, opts ...Option) *Pool {
+	if size < 1 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	p := &Pool{
+		queueSize:    size,
+		panicHandler: logPanic,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.queueSize < 0 {
+		p.queueSize = 0
+	}
+	p.cond = sync.NewCond(&p.mu)
+	p.space = sync.NewCond(&p.mu)
+	p.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// Submit queues task for execution with Normal priority. When the queue is
+// full it returns ErrQueueFull, or blocks if the pool was created with
+// WithBlockingSubmit. It returns ErrClosed once Shutdown has been called.
+func (p *Pool) Submit(task func()) error {
+	return p.SubmitPriority(Normal, task)
+}
+
+// SubmitPriority is like Submit but queues task with the given priority.
+// Priorities outside [Low, High] are clamped to that range.
+func (p *Pool) SubmitPriority(prio Priority, task func()) error {
+	return p.SubmitContext(context.Background(), prio, task)
+}
+
+// SubmitContext is like SubmitPriority, but a submission blocked by
+// WithBlockingSubmit gives up and returns ctx.Err() once ctx is done. It also
+// returns ctx.Err() without queuing task if ctx is already done.
+func (p *Pool) SubmitContext(ctx context.Context, prio Priority, task func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	prio = max(Low, min(prio, High))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	// Idle workers take tasks right away, so they do not count against the
+	// queue size.
+	if p.queued >= p.queueSize+p.idle {
+		if !p.blocking {
+			return ErrQueueFull
+		}
+		stop := context.AfterFunc(ctx, func() {
+			p.mu.Lock()
+			p.space.Broadcast()
+			p.mu.Unlock()
+		})
+		defer stop()
+		for p.queued >= p.queueSize+p.idle {
+			p.space.Wait()
+			if p.closed {
+				return ErrClosed
+			}
+			if err := ctx.Err(); err != nil {
+				// Pass on a wakeup this producer may have consumed.
+				if p.queued < p.queueSize+p.idle {
+					p.space.Signal()
+				}
+				return err
+			}
+		}
+	}
+	p.queues[prio] = append(p.queues[prio], task)
+	p.queued++
+	p.cond.Signal()
+	return nil
+}
+
+// Shutdown stops accepting new tasks and waits for the queued and running
+// ones to finish. If ctx is done first, Shutdown returns ctx.Err() while the
+// workers keep draining the queue in the background.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.space.Broadcast()
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// QueueLen returns the number of tasks waiting for a worker.
+func (p *Pool) QueueLen() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queued
+}
+
+// QueueSize returns the maximum number of tasks that may wait for a worker
+// while every worker is busy.
+func (p *Pool) QueueSize() int {
+	return p.queueSize
+}
+
+func (p *Pool) worker() {
+	defer p.wg.Done()
+	for {
+		task, ok := p.next()
+		if !ok {
+			return
+		}
+		p.run(task)
+	}
+}
+
+// next blocks until a task is available and pops it. It returns false once
+// the pool is closed and the queue is drained.
+func (p *Pool) next() (func(), bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.queued == 0 && !p.closed {
+		p.idle++
+		p.space.Signal()
+		p.cond.Wait()
+		p.idle--
+	}
+	for prio := High; prio >= Low; prio-- {
+		if q := p.queues[prio]; len(q) > 0 {
+			task := q[0]
+			q[0] = nil
+			p.queues[prio] = q[1:]
+			p.queued--
+			p.space.Signal()
+			return task, true
+		}
+	}
+	return nil, false
+}
+
+func (p *Pool) run(task func()) {
+	defer func() {
+		if r := recover(); r != nil && p.panicHandler != nil {
+			p.panicHandler(r, debug.Stack())
+		}
+	}()
+	task()
+}
+
+func logPanic(v any, stack []byte) {
+	log.Printf("pool: task panicked: %v\n%s", v, stack)
+}
@@ -0,0 +1,377 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// occupy submits n tasks that block until release is called, and waits for
+// all of them to be running so that no worker is idle.
+func occupy(t *testing.T, p *Pool, n int) (release func()) {
+	t.Helper()
+	var started sync.WaitGroup
+	block := make(chan struct{})
+	started.Add(n)
+	for i := 0; i < n; i++ {
+		err := p.Submit(func() {
+			started.Done()
+			<-block
+		})
+		if err != nil {
+			t.Fatalf("Submit() = %v, want nil", err)
+		}
+	}
+	started.Wait()
+	var once sync.Once
+	return func() { once.Do(func() { close(block) }) }
+}
+
+func shutdown(t *testing.T, p *Pool) {
+	t.Helper()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v, want nil", err)
+	}
+}
+
+func TestPool_Submit(t *testing.T) {
+	t.Run("runs tasks", func(t *testing.T) {
+		p := New(4)
+		var n atomic.Int32
+		var wg sync.WaitGroup
+		wg.Add(10)
+		for i := 0; i < 10; i++ {
+			for p.Submit(func() { n.Add(1); wg.Done() }) != nil {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		wg.Wait()
+		shutdown(t, p)
+
+		if got := n.Load(); got != 10 {
+			t.Fatalf("ran %d tasks, want 10", got)
+		}
+	})
+
+	t.Run("returns ErrQueueFull when the queue is at capacity", func(t *testing.T) {
+		p := New(1, WithQueueSize(2))
+		release := occupy(t, p, 1)
+		defer shutdown(t, p)
+		defer release()
+
+		for i := 0; i < 2; i++ {
+			if err := p.Submit(func() {}); err != nil {
+				t.Fatalf("Submit() #%d = %v, want nil", i, err)
+			}
+		}
+		if err := p.Submit(func() {}); err != ErrQueueFull {
+			t.Fatalf("Submit() = %v, want %v", err, ErrQueueFull)
+		}
+	})
+
+	t.Run("returns ErrClosed after Shutdown", func(t *testing.T) {
+		p := New(1)
+		shutdown(t, p)
+
+		if err := p.Submit(func() {}); err != ErrClosed {
+			t.Fatalf("Submit() = %v, want %v", err, ErrClosed)
+		}
+	})
+}
+
+func TestPool_Shutdown(t *testing.T) {
+	t.Run("drains queued tasks", func(t *testing.T) {
+		p := New(1, WithQueueSize(5))
+		release := occupy(t, p, 1)
+		var n atomic.Int32
+		for i := 0; i < 5; i++ {
+			if err := p.Submit(func() { n.Add(1) }); err != nil {
+				t.Fatalf("Submit() = %v, want nil", err)
+			}
+		}
+
+		release()
+		shutdown(t, p)
+
+		if got := n.Load(); got != 5 {
+			t.Fatalf("ran %d queued tasks, want 5", got)
+		}
+	})
+
+	t.Run("returns ctx.Err on timeout", func(t *testing.T) {
+		p := New(1)
+		release := occupy(t, p, 1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+		}
+
+		release()
+		shutdown(t, p)
+	})
+
+	t.Run("can be called more than once", func(t *testing.T) {
+		p := New(2)
+		shutdown(t, p)
+		shutdown(t, p)
+	})
+}
+
+func TestPool_panics(t *testing.T) {
+	t.Run("hands the value and stack to the panic handler", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			values []any
+			stacks [][]byte
+		)
+		p := New(1, WithPanicHandler(func(v any, stack []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+			values = append(values, v)
+			stacks = append(stacks, stack)
+		}))
+
+		for p.Submit(func() { panic("boom") }) != nil {
+			time.Sleep(time.Millisecond)
+		}
+		ran := make(chan struct{})
+		for p.Submit(func() { close(ran) }) != nil {
+			time.Sleep(time.Millisecond)
+		}
+		<-ran // the worker survived the panic
+		shutdown(t, p)
+
+		if len(values) != 1 || values[0] != "boom" {
+			t.Fatalf("panic handler got %v, want [boom]", values)
+		}
+		if !strings.Contains(string(stacks[0]), "pool_test.go") {
+			t.Fatalf("stack does not point at the panicking task:\n%s", stacks[0])
+		}
+	})
+
+	t.Run("logs panics by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		defer log.SetOutput(log.Writer())
+		log.SetOutput(&buf)
+
+		p := New(1)
+		for p.Submit(func() { panic("boom") }) != nil {
+			time.Sleep(time.Millisecond)
+		}
+		shutdown(t, p)
+
+		if out := buf.String(); !strings.Contains(out, "pool: task panicked: boom") {
+			t.Fatalf("log output = %q, want the panic value", out)
+		}
+	})
+}
+
+func TestPool_SubmitPriority(t *testing.T) {
+	t.Run("dispatches by priority and FIFO within a level", func(t *testing.T) {
+		p := New(1, WithQueueSize(9))
+		release := occupy(t, p, 1)
+
+		var got []string
+		record := func(s string) func() {
+			return func() { got = append(got, s) }
+		}
+		submits := []struct {
+			prio Priority
+			name string
+		}{
+			{Low, "low1"}, {Normal, "normal1"}, {High, "high1"},
+			{Low, "low2"}, {High, "high2"}, {Normal, "normal2"},
+			{Normal, "normal3"}, {Low, "low3"}, {High, "high3"},
+		}
+		for _, s := range submits {
+			if err := p.SubmitPriority(s.prio, record(s.name)); err != nil {
+				t.Fatalf("SubmitPriority(%v) = %v, want nil", s.prio, err)
+			}
+		}
+
+		release()
+		shutdown(t, p)
+
+		want := []string{
+			"high1", "high2", "high3",
+			"normal1", "normal2", "normal3",
+			"low1", "low2", "low3",
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("dispatch order = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("clamps out of range priorities", func(t *testing.T) {
+		p := New(1, WithQueueSize(2))
+		release := occupy(t, p, 1)
+
+		var got []string
+		if err := p.SubmitPriority(Low-1, func() { got = append(got, "below") }); err != nil {
+			t.Fatalf("SubmitPriority() = %v, want nil", err)
+		}
+		if err := p.SubmitPriority(High+1, func() { got = append(got, "above") }); err != nil {
+			t.Fatalf("SubmitPriority() = %v, want nil", err)
+		}
+
+		release()
+		shutdown(t, p)
+
+		if strings.Join(got, ",") != "above,below" {
+			t.Fatalf("dispatch order = %v, want [above below]", got)
+		}
+	})
+}
+
+// submitAsync runs submit on a new goroutine and returns its result channel.
+func submitAsync(submit func() error) <-chan error {
+	ch := make(chan error, 1)
+	go func() { ch <- submit() }()
+	return ch
+}
+
+func assertBlocked(t *testing.T, ch <-chan error) {
+	t.Helper()
+	select {
+	case err := <-ch:
+		t.Fatalf("submission returned %v, want it to block", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func receive(t *testing.T, ch <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("submission is still blocked")
+		return nil
+	}
+}
+
+func TestPool_blockingSubmit(t *testing.T) {
+	t.Run("waits for room in the queue", func(t *testing.T) {
+		p := New(1, WithQueueSize(1), WithBlockingSubmit())
+		release := occupy(t, p, 1)
+		if err := p.Submit(func() {}); err != nil {
+			t.Fatalf("Submit() = %v, want nil", err)
+		}
+
+		ran := make(chan struct{})
+		ch := submitAsync(func() error { return p.Submit(func() { close(ran) }) })
+		assertBlocked(t, ch)
+
+		release()
+		if err := receive(t, ch); err != nil {
+			t.Fatalf("Submit() = %v, want nil", err)
+		}
+		<-ran
+		shutdown(t, p)
+	})
+
+	t.Run("returns ErrClosed when the pool shuts down", func(t *testing.T) {
+		p := New(1, WithQueueSize(0), WithBlockingSubmit())
+		release := occupy(t, p, 1)
+
+		ch := submitAsync(func() error { return p.Submit(func() {}) })
+		assertBlocked(t, ch)
+
+		done := make(chan error, 1)
+		go func() { done <- p.Shutdown(context.Background()) }()
+		if err := receive(t, ch); err != ErrClosed {
+			t.Fatalf("Submit() = %v, want %v", err, ErrClosed)
+		}
+		release()
+		if err := <-done; err != nil {
+			t.Fatalf("Shutdown() = %v, want nil", err)
+		}
+	})
+}
+
+func TestPool_SubmitContext(t *testing.T) {
+	t.Run("returns ctx.Err when the context is done while blocked", func(t *testing.T) {
+		p := New(1, WithQueueSize(0), WithBlockingSubmit())
+		release := occupy(t, p, 1)
+		defer shutdown(t, p)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := p.SubmitContext(ctx, Normal, func() {}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("SubmitContext() = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if n := p.QueueLen(); n != 0 {
+			t.Fatalf("QueueLen() = %d, want 0", n)
+		}
+	})
+
+	t.Run("does not queue with a done context", func(t *testing.T) {
+		p := New(1)
+		defer shutdown(t, p)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := p.SubmitContext(ctx, Normal, func() {}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("SubmitContext() = %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("hands room over to other producers when giving up", func(t *testing.T) {
+		p := New(1, WithQueueSize(1), WithBlockingSubmit())
+		release := occupy(t, p, 1)
+		if err := p.Submit(func() {}); err != nil {
+			t.Fatalf("Submit() = %v, want nil", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		canceled := submitAsync(func() error { return p.SubmitContext(ctx, Normal, func() {}) })
+		other := submitAsync(func() error { return p.Submit(func() {}) })
+		assertBlocked(t, canceled)
+		assertBlocked(t, other)
+
+		cancel()
+		if err := receive(t, canceled); !errors.Is(err, context.Canceled) {
+			t.Fatalf("SubmitContext() = %v, want %v", err, context.Canceled)
+		}
+		release()
+		if err := receive(t, other); err != nil {
+			t.Fatalf("Submit() = %v, want nil", err)
+		}
+		shutdown(t, p)
+	})
+}
+
+func TestPool_queueIntrospection(t *testing.T) {
+	p := New(1, WithQueueSize(3))
+	release := occupy(t, p, 1)
+
+	if n := p.QueueSize(); n != 3 {
+		t.Fatalf("QueueSize() = %d, want 3", n)
+	}
+	for i := 0; i < 3; i++ {
+		if n := p.QueueLen(); n != i {
+			t.Fatalf("QueueLen() = %d, want %d", n, i)
+		}
+		if err := p.Submit(func() {}); err != nil {
+			t.Fatalf("Submit() = %v, want nil", err)
+		}
+	}
+	if n := p.QueueLen(); n != 3 {
+		t.Fatalf("QueueLen() = %d, want 3", n)
+	}
+
+	release()
+	shutdown(t, p)
+	if n := p.QueueLen(); n != 0 {
+		t.Fatalf("QueueLen() = %d after Shutdown, want 0", n)
+	}
+}
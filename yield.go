@@ -0,0 +1,36 @@
+package promise
+
+import (
+	"context"
+	"runtime"
+)
+
+type heartbeatKey struct{}
+
+// WithHeartbeat returns a copy of ctx that carries fn. Every call to Yield
+// with the returned context (or one derived from it) invokes fn, which makes
+// it a cheap place to record progress ticks from CPU-bound loops.
+func WithHeartbeat(ctx context.Context, fn func()) context.Context {
+	return context.WithValue(ctx, heartbeatKey{}, fn)
+}
+
+// Yield is a cooperative cancellation point for CPU-bound work. It returns
+// ctx.Err() if the context is done; otherwise it records a heartbeat (see
+// WithHeartbeat), yields the processor with runtime.Gosched and returns nil.
+//
+//	for i := range items {
+//		if err := promise.Yield(ctx); err != nil {
+//			return zero, err
+//		}
+//		// ...
+//	}
+func Yield(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if fn, ok := ctx.Value(heartbeatKey{}).(func()); ok && fn != nil {
+		fn()
+	}
+	runtime.Gosched()
+	return nil
+}
@@ -0,0 +1,55 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestYield(t *testing.T) {
+	t.Run("returns ctx.Err without a heartbeat on a canceled context", func(t *testing.T) {
+		calls := 0
+		ctx, cancel := context.WithCancel(WithHeartbeat(context.Background(), func() { calls++ }))
+		cancel()
+
+		if err := Yield(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Yield() = %v, want %v", err, context.Canceled)
+		}
+		if calls != 0 {
+			t.Fatalf("heartbeat called %d times, want 0", calls)
+		}
+	})
+
+	t.Run("calls the heartbeat on a live context", func(t *testing.T) {
+		calls := 0
+		ctx := WithHeartbeat(context.Background(), func() { calls++ })
+
+		for i := 0; i < 3; i++ {
+			if err := Yield(ctx); err != nil {
+				t.Fatalf("Yield() = %v, want nil", err)
+			}
+		}
+		if calls != 3 {
+			t.Fatalf("heartbeat called %d times, want 3", calls)
+		}
+	})
+
+	t.Run("calls the heartbeat through derived contexts", func(t *testing.T) {
+		calls := 0
+		ctx, cancel := context.WithCancel(WithHeartbeat(context.Background(), func() { calls++ }))
+		defer cancel()
+
+		if err := Yield(ctx); err != nil {
+			t.Fatalf("Yield() = %v, want nil", err)
+		}
+		if calls != 1 {
+			t.Fatalf("heartbeat called %d times, want 1", calls)
+		}
+	})
+
+	t.Run("works without a heartbeat", func(t *testing.T) {
+		if err := Yield(context.Background()); err != nil {
+			t.Fatalf("Yield() = %v, want nil", err)
+		}
+	})
+}